@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/uchimanajet7/rds-try/command"
+)
+
+// ClusterRestoreCommand implements the "cluster-restore" subcommand: restore an
+// Aurora DB Cluster from its source cluster's latest available snapshot and create
+// its first cluster instance.
+type ClusterRestoreCommand struct {
+	Command *command.Command
+}
+
+func (c *ClusterRestoreCommand) Help() string {
+	return `Usage: rds-try cluster-restore [options] <source-cluster-identifier> <new-cluster-identifier>
+
+  Restores a new Aurora DB Cluster from <source-cluster-identifier>'s latest
+  available snapshot, then creates a single cluster instance so the new
+  cluster has an endpoint to connect to.
+
+Options:
+
+  -instance-class string   DB Instance class for the cluster's first instance (required)
+  -instance-identifier string
+                            Identifier for the cluster's first instance (required)
+`
+}
+
+func (c *ClusterRestoreCommand) Synopsis() string {
+	return "Restore an Aurora DB Cluster from its source's latest snapshot"
+}
+
+func (c *ClusterRestoreCommand) Run(args []string) int {
+	var instanceClass, instanceIdentifier string
+
+	flags := flag.NewFlagSet("cluster-restore", flag.ContinueOnError)
+	flags.StringVar(&instanceClass, "instance-class", "", "DB Instance class for the cluster's first instance")
+	flags.StringVar(&instanceIdentifier, "instance-identifier", "", "identifier for the cluster's first instance")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if flags.NArg() != 2 || instanceClass == "" || instanceIdentifier == "" {
+		fmt.Println(c.Help())
+		return 1
+	}
+	sourceIdentifier, newIdentifier := flags.Arg(0), flags.Arg(1)
+
+	snapshot, err := c.Command.DescribeLatestDBClusterSnapshot(sourceIdentifier)
+	if err != nil {
+		return 1
+	}
+
+	if _, err := c.Command.RestoreDBClusterFromSnapshot(&command.RestoreDBClusterFromSnapshotArgs{
+		DBInstanceClass:    instanceClass,
+		ClusterIdentifier:  newIdentifier,
+		InstanceIdentifier: instanceIdentifier,
+		Snapshot:           snapshot,
+	}); err != nil {
+		return 1
+	}
+
+	return 0
+}
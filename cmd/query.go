@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/uchimanajet7/rds-try/command"
+	"github.com/uchimanajet7/rds-try/config"
+	"github.com/uchimanajet7/rds-try/query"
+)
+
+// QueryCommand implements the "query" subcommand: run one or more SQL queries
+// against a DB Instance and, unless -no-output is set, write their results through
+// an OutputSink selected by -format.
+type QueryCommand struct {
+	Command *command.Command
+}
+
+func (c *QueryCommand) Help() string {
+	return `Usage: rds-try query [options] <db-identifier>
+
+  Runs one or more queries against <db-identifier> and times each one.
+
+Options:
+
+  -sql name=SQL    A query to run, as name=SQL (repeatable, required)
+  -format string   Output format: "csv" (default), "ndjson", or "parquet"
+  -root string     Output directory, or S3 key prefix when -s3-bucket is set
+  -s3-bucket string
+                   Write output to this S3 bucket instead of a local file
+  -bom             Prepend a UTF-8 BOM note to CSV output
+  -no-output       Run the queries without writing a result file
+`
+}
+
+func (c *QueryCommand) Synopsis() string {
+	return "Run SQL queries against a DB Instance and write their results"
+}
+
+func (c *QueryCommand) Run(args []string) int {
+	var format, root, s3Bucket string
+	var bom, noOutput bool
+	var queries queryList
+
+	flags := flag.NewFlagSet("query", flag.ContinueOnError)
+	flags.Var(&queries, "sql", "a query to run, as name=SQL (repeatable, required)")
+	flags.StringVar(&format, "format", "", `output format: "csv" (default), "ndjson", or "parquet"`)
+	flags.StringVar(&root, "root", "", "output directory, or S3 key prefix when -s3-bucket is set")
+	flags.StringVar(&s3Bucket, "s3-bucket", "", "write output to this S3 bucket instead of a local file")
+	flags.BoolVar(&bom, "bom", false, "prepend a UTF-8 BOM note to CSV output")
+	flags.BoolVar(&noOutput, "no-output", false, "run the queries without writing a result file")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if flags.NArg() != 1 || len(queries) == 0 {
+		fmt.Println(c.Help())
+		return 1
+	}
+
+	c.Command.OutConfig = config.OutConfig{
+		File:     !noOutput,
+		Root:     root,
+		Bom:      bom,
+		Format:   format,
+		S3Bucket: s3Bucket,
+	}
+
+	instance, err := c.Command.DescribeDBInstance(flags.Arg(0))
+	if err != nil {
+		return 1
+	}
+
+	if _, err := c.Command.ExecuteSQL(&command.ExecuteSQLArgs{
+		Engine:   *instance.Engine,
+		Endpoint: instance.Endpoint,
+		Queries:  queries.asQueries(),
+	}); err != nil {
+		return 1
+	}
+
+	return 0
+}
+
+// queryList collects repeated -sql name=SQL flags into query.Query values.
+type queryList []string
+
+func (q *queryList) String() string {
+	return strings.Join(*q, ",")
+}
+
+func (q *queryList) Set(value string) error {
+	*q = append(*q, value)
+	return nil
+}
+
+func (q queryList) asQueries() []query.Query {
+	queries := make([]query.Query, 0, len(q))
+	for _, nv := range q {
+		parts := strings.SplitN(nv, "=", 2)
+		name, sql := parts[0], ""
+		if len(parts) == 2 {
+			sql = parts[1]
+		}
+		queries = append(queries, query.Query{Name: name, Sql: sql})
+	}
+
+	return queries
+}
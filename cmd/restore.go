@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/uchimanajet7/rds-try/command"
+)
+
+// RestoreCommand implements the "restore" subcommand: restore a DB Instance from the
+// source DB Instance's latest available snapshot, optionally into another region.
+type RestoreCommand struct {
+	Command *command.Command
+}
+
+func (c *RestoreCommand) Help() string {
+	return `Usage: rds-try restore [options] <source-db-identifier> <new-db-identifier>
+
+  Restores a new DB Instance from <source-db-identifier>'s latest available
+  snapshot. With -dst-region, the snapshot is copied to that region first and
+  the new DB Instance is restored there instead.
+
+Options:
+
+  -instance-class string  DB Instance class for the restored instance (required)
+  -multi-az               Restore as a Multi-AZ deployment
+  -dst-region string      Copy the snapshot to this region and restore there
+  -dst-subnet-group string
+                          DB subnet group in -dst-region (required with -dst-region,
+                          since subnet groups are region-scoped)
+  -kms-key-id string      KMS key ID for the cross-region copy, if the source is encrypted
+
+  Snapshot selection (all optional; default is the latest available snapshot):
+
+  -snapshot-type string   "automated"|"manual"|"shared"|"public"|"awsbackup"
+  -include-shared         Consider snapshots shared from other accounts
+  -include-public         Consider public snapshots
+  -tag key=value          Require this exact tag on the snapshot (repeatable)
+
+  Cross-region wait tuning (overrides the operator's configured RDSConfig
+  waiter settings for this run only):
+
+  -dst-timeout duration   How long to wait for the copied snapshot to become
+                          available in -dst-region (e.g. "45m")
+`
+}
+
+func (c *RestoreCommand) Synopsis() string {
+	return "Restore a DB Instance from its source's latest snapshot"
+}
+
+func (c *RestoreCommand) Run(args []string) int {
+	var instanceClass, dstRegion, dstSubnetGroup, kmsKeyID, snapshotType string
+	var multiAZ, includeShared, includePublic bool
+	var dstTimeout time.Duration
+	var tags tagList
+
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	flags.StringVar(&instanceClass, "instance-class", "", "DB Instance class for the restored instance")
+	flags.BoolVar(&multiAZ, "multi-az", false, "restore as a Multi-AZ deployment")
+	flags.StringVar(&dstRegion, "dst-region", "", "copy the snapshot to this region and restore there")
+	flags.StringVar(&dstSubnetGroup, "dst-subnet-group", "", "DB subnet group in -dst-region")
+	flags.StringVar(&kmsKeyID, "kms-key-id", "", "KMS key ID for the cross-region copy")
+	flags.StringVar(&snapshotType, "snapshot-type", "", `"automated"|"manual"|"shared"|"public"|"awsbackup"`)
+	flags.BoolVar(&includeShared, "include-shared", false, "consider snapshots shared from other accounts")
+	flags.BoolVar(&includePublic, "include-public", false, "consider public snapshots")
+	flags.Var(&tags, "tag", "require this exact tag on the snapshot, as key=value (repeatable)")
+	flags.DurationVar(&dstTimeout, "dst-timeout", 0, `how long to wait for the copy to become available in -dst-region, e.g. "45m"`)
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if flags.NArg() != 2 || instanceClass == "" {
+		fmt.Println(c.Help())
+		return 1
+	}
+	sourceIdentifier, newIdentifier := flags.Arg(0), flags.Arg(1)
+
+	source, err := c.Command.DescribeDBInstance(sourceIdentifier)
+	if err != nil {
+		return 1
+	}
+
+	snapshot, err := c.Command.FindDBSnapshot(command.SnapshotQuery{
+		DBInstanceIdentifier: sourceIdentifier,
+		SnapshotType:         snapshotType,
+		IncludeShared:        includeShared,
+		IncludePublic:        includePublic,
+		MostRecent:           true,
+		TagFilters:           tags.asMap(),
+	})
+	if err != nil {
+		return 1
+	}
+
+	restoreArgs := &command.RestoreDBInstanceFromDBSnapshotArgs{
+		DBInstanceClass: instanceClass,
+		DBIdentifier:    newIdentifier,
+		MultiAZ:         multiAZ,
+		Snapshot:        snapshot,
+		Instance:        source,
+	}
+
+	if dstRegion == "" {
+		if _, err := c.Command.RestoreDBInstanceFromDBSnapshot(restoreArgs); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	if dstSubnetGroup == "" {
+		fmt.Println(c.Help())
+		return 1
+	}
+
+	copyArgs := &command.CopyDBSnapshotArgs{
+		SourceARN:        c.Command.ARNPrefix + "snapshot:" + *snapshot.DBSnapshotIdentifier,
+		TargetIdentifier: *snapshot.DBSnapshotIdentifier,
+		DstRegion:        dstRegion,
+		KmsKeyId:         kmsKeyID,
+		CopyTags:         true,
+		Timeout:          dstTimeout,
+	}
+
+	if _, err := c.Command.CopyAndRestoreDBInstance(&command.CopyAndRestoreDBInstanceArgs{
+		Copy:               copyArgs,
+		Restore:            restoreArgs,
+		DstSubnetGroupName: dstSubnetGroup,
+	}); err != nil {
+		return 1
+	}
+
+	return 0
+}
+
+// tagList collects repeated -tag key=value flags into the map FindDBSnapshot's
+// SnapshotQuery.TagFilters expects.
+type tagList []string
+
+func (t *tagList) String() string {
+	return strings.Join(*t, ",")
+}
+
+func (t *tagList) Set(value string) error {
+	*t = append(*t, value)
+	return nil
+}
+
+func (t tagList) asMap() map[string]string {
+	if len(t) == 0 {
+		return nil
+	}
+
+	m := make(map[string]string, len(t))
+	for _, kv := range t {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 2 {
+			m[parts[0]] = parts[1]
+		} else {
+			m[parts[0]] = ""
+		}
+	}
+
+	return m
+}
@@ -2,15 +2,17 @@ package command
 
 import (
 	"database/sql"
-	"encoding/csv"
+	"database/sql/driver"
 	"errors"
 	"fmt"
-	"os"
-	"path"
+	"sort"
 	"strings"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql" // required as SQL driver at the time of connection
+	_ "github.com/denisenkom/go-mssqldb" // required as SQL driver at the time of connection
+	_ "github.com/go-sql-driver/mysql"   // required as SQL driver at the time of connection
+	_ "github.com/lib/pq"                // required as SQL driver at the time of connection
+	_ "github.com/sijms/go-ora/v2"       // required as SQL driver at the time of connection
 
 	"github.com/awslabs/aws-sdk-go/aws"
 	"github.com/awslabs/aws-sdk-go/gen/rds"
@@ -37,6 +39,12 @@ type Command struct {
 	RDSConfig config.RDSConfig
 	RDSClient *rds.RDS
 	ARNPrefix string
+
+	// Creds and Region are kept around so a second client (a cross-region *rds.RDS,
+	// or the S3 client an -s3-bucket sink uses) can be built without asking
+	// the caller to plumb credentials through twice
+	Creds  *aws.Credentials
+	Region string
 }
 
 var log = logger.GetLogger("command")
@@ -47,6 +55,12 @@ var (
 	ErrDriverNotFound      = errors.New("DB　Driver is not found")
 	ErrRdsTypesNotFound    = errors.New("RDS Types is not found")
 	ErrRdsARNsNotFound     = errors.New("RDS ARN Types is not found")
+	ErrSnapshotNotManaged  = errors.New("DB Snapshot is not managed by rds-try")
+
+	ErrClusterNotFound         = errors.New("DB Cluster is not found")
+	ErrClusterSnapshotNotFound = errors.New("DB Cluster Snapshot is not found")
+
+	ErrDstSubnetGroupRequired = errors.New("a destination-region DB subnet group is required for cross-region restore")
 )
 
 func (c *Command) describeDBInstances(message *rds.DescribeDBInstancesMessage) ([]rds.DBInstance, error) {
@@ -216,28 +230,128 @@ func (c *Command) RestoreDBInstanceFromDBSnapshot(args *RestoreDBInstanceFromDBS
 	return resp.DBInstance, err
 }
 
-func (c *Command) DescribeDBSnapshotsByTags() ([]rds.DBSnapshot, error) {
-	message := &rds.DescribeDBSnapshotsMessage{}
+// build an *rds.RDS client for a different AWS region using the same credentials as
+// the primary RDSClient, used for cross-region snapshot copy
+func (c *Command) getDstRDSClient(dstRegion string) *rds.RDS {
+	return rds.New(c.Creds, dstRegion, nil)
+}
 
-	resp, err := c.describeDBSnapshots(message)
+type CopyDBSnapshotArgs struct {
+	SourceARN        string // full ARN, so the copy can cross regions
+	TargetIdentifier string
+	DstRegion        string
+	KmsKeyId         string
+	CopyTags         bool
+	Timeout          time.Duration // cross-region copies of large snapshots often exceed 30 minutes
+}
+
+func (c *Command) CopyDBSnapshot(args *CopyDBSnapshotArgs) (*rds.DBSnapshot, error) {
+	dstClient := c.getDstRDSClient(args.DstRegion)
+
+	message := &rds.CopyDBSnapshotMessage{
+		SourceDBSnapshotIdentifier: aws.String(args.SourceARN),
+		TargetDBSnapshotIdentifier: aws.String(args.TargetIdentifier),
+		CopyTags:                   aws.Boolean(args.CopyTags),
+		Tags:                       getSpecifyTags(), // keep the copy rds-try-managed too
+	}
+	if args.KmsKeyId != "" {
+		message.KmsKeyID = aws.String(args.KmsKeyId)
+	}
+
+	resp, err := dstClient.CopyDBSnapshot(message)
 
 	if err != nil {
+		log.Errorf("%s", err.Error())
 		return nil, err
 	}
 
-	var dbSnapshots []rds.DBSnapshot
-	for _, snapshot := range resp {
-		state, err := c.checkListTagsForResourceMessage(snapshot)
-		if err != nil {
-			return nil, err
-		}
+	return resp.DBSnapshot, err
+}
 
-		if state {
-			dbSnapshots = append(dbSnapshots, snapshot)
-		}
+type CopyAndRestoreDBInstanceArgs struct {
+	Copy    *CopyDBSnapshotArgs
+	Restore *RestoreDBInstanceFromDBSnapshotArgs
+
+	// DstSubnetGroupName must name a DB subnet group that already exists in
+	// Copy.DstRegion. DB subnet groups are region-scoped, so Restore.Instance's own
+	// subnet group (read from the source region) can never be valid there.
+	DstSubnetGroupName string
+}
+
+// arnPrefixForRegion rewrites the region segment of an ARN prefix of the form
+// "arn:aws:rds:<region>:<account-id>:" so cross-region operations tag and look up
+// resources under the destination region instead of the source's.
+func arnPrefixForRegion(prefix, region string) string {
+	parts := strings.Split(prefix, ":")
+	if len(parts) > 3 {
+		parts[3] = region
 	}
 
-	return dbSnapshots, err
+	return strings.Join(parts, ":")
+}
+
+// copy the latest snapshot to another region and restore a new DB Instance there in
+// one step, so users can "copy latest snapshot to region X and restore there"
+func (c *Command) CopyAndRestoreDBInstance(args *CopyAndRestoreDBInstanceArgs) (*rds.DBInstance, error) {
+	if args.DstSubnetGroupName == "" {
+		log.Errorf("%s", ErrDstSubnetGroupRequired.Error())
+		return nil, ErrDstSubnetGroupRequired
+	}
+
+	dst := &Command{
+		OutConfig: c.OutConfig,
+		RDSConfig: c.RDSConfig,
+		RDSClient: c.getDstRDSClient(args.Copy.DstRegion),
+		ARNPrefix: arnPrefixForRegion(c.ARNPrefix, args.Copy.DstRegion),
+		Region:    args.Copy.DstRegion,
+		Creds:     c.Creds,
+	}
+
+	snapshot, err := c.CopyDBSnapshot(args.Copy)
+	if err != nil {
+		return nil, err
+	}
+
+	// the same Waiter chunk0-5 gives WaitForStatusAvailable, built from dst's own
+	// operator-tunable RDSConfig fields via defaultWaiter rather than the bare
+	// package defaults, so a failed copy returns immediately instead of only timing
+	// out after whatever the configured timeout is
+	waiter := dst.defaultWaiter()
+	if args.Copy.Timeout > 0 {
+		waiter.Timeout = args.Copy.Timeout
+	}
+
+	result := <-dst.WaitForStatusAvailable(snapshot, waiter)
+	if result.Err != nil {
+		log.Errorf("%s", result.Err.Error())
+		return nil, result.Err
+	}
+	if !result.Available {
+		log.Errorf("%s", ErrSnapshotNotFound.Error())
+		return nil, ErrSnapshotNotFound
+	}
+
+	args.Restore.Snapshot = snapshot
+	args.Restore.Instance.DBSubnetGroup = &rds.DBSubnetGroup{
+		DBSubnetGroupName: aws.String(args.DstSubnetGroupName),
+	}
+
+	return dst.RestoreDBInstanceFromDBSnapshot(args.Restore)
+}
+
+// DescribeDBSnapshotsByTags can't go through FindDBSnapshot itself — it wants every
+// rds-try-managed snapshot regardless of status, not one "available" result — but it
+// shares filterDBSnapshots with FindDBSnapshot so there's still a single loop walking
+// DescribeDBSnapshots responses.
+func (c *Command) DescribeDBSnapshotsByTags() ([]rds.DBSnapshot, error) {
+	resp, err := c.describeDBSnapshots(&rds.DescribeDBSnapshotsMessage{})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.filterDBSnapshots(resp, false, func(snapshot rds.DBSnapshot) (bool, error) {
+		return c.checkListTagsForResourceMessage(snapshot)
+	})
 }
 
 func (c *Command) describeDBSnapshots(message *rds.DescribeDBSnapshotsMessage) ([]rds.DBSnapshot, error) {
@@ -251,36 +365,126 @@ func (c *Command) describeDBSnapshots(message *rds.DescribeDBSnapshotsMessage) (
 	return resp.DBSnapshots, err
 }
 
-// the target only "available"
-func (c *Command) DescribeLatestDBSnapshot(dbIdentifier string) (*rds.DBSnapshot, error) {
+// SnapshotQuery selects a single DB Snapshot for FindDBSnapshot. TagFilters is an
+// exact key/value match, separate from the rt_name/rt_time gate checkListTagsForResourceMessage
+// applies to rds-try-managed resources.
+type SnapshotQuery struct {
+	DBInstanceIdentifier string
+	SnapshotType         string // "automated"|"manual"|"shared"|"public"|"awsbackup"
+	IncludeShared        bool
+	IncludePublic        bool
+	MostRecent           bool
+	TagFilters           map[string]string
+}
+
+// FindDBSnapshot is the one selector DescribeLatestDBSnapshot and the restore
+// subcommand's filters should go through, so "available" filtering and most-recent
+// sorting live in a single place
+func (c *Command) FindDBSnapshot(q SnapshotQuery) (*rds.DBSnapshot, error) {
 	message := &rds.DescribeDBSnapshotsMessage{
-		DBInstanceIdentifier: aws.String(dbIdentifier),
+		IncludeShared: aws.Boolean(q.IncludeShared),
+		IncludePublic: aws.Boolean(q.IncludePublic),
+	}
+	if q.DBInstanceIdentifier != "" {
+		message.DBInstanceIdentifier = aws.String(q.DBInstanceIdentifier)
+	}
+	if q.SnapshotType != "" {
+		message.SnapshotType = aws.String(q.SnapshotType)
 	}
 
 	resp, err := c.describeDBSnapshots(message)
+	if err != nil {
+		return nil, err
+	}
+
+	var keep func(rds.DBSnapshot) (bool, error)
+	if len(q.TagFilters) > 0 {
+		keep = func(snapshot rds.DBSnapshot) (bool, error) {
+			return c.matchesTagFilters(snapshot, q.TagFilters)
+		}
+	}
 
+	dbSnapshots, err := c.filterDBSnapshots(resp, true, keep)
 	if err != nil {
 		return nil, err
 	}
 
-	// want to filter by status "available"
+	db_len := len(dbSnapshots)
+	if db_len < 1 {
+		log.Errorf("%s", ErrSnapshotNotFound.Error())
+		return nil, ErrSnapshotNotFound
+	}
+
+	if q.MostRecent {
+		sort.Slice(dbSnapshots, func(i, j int) bool {
+			return dbSnapshots[i].SnapshotCreateTime.After(*dbSnapshots[j].SnapshotCreateTime)
+		})
+		return &dbSnapshots[0], nil
+	}
+
+	return &dbSnapshots[db_len-1], err
+}
+
+// filterDBSnapshots walks a DescribeDBSnapshots response once, optionally requiring
+// "available" status and/or a per-snapshot predicate. FindDBSnapshot and
+// DescribeDBSnapshotsByTags both go through this instead of keeping their own
+// independent filtering loops.
+func (c *Command) filterDBSnapshots(resp []rds.DBSnapshot, requireAvailable bool, keep func(rds.DBSnapshot) (bool, error)) ([]rds.DBSnapshot, error) {
 	var dbSnapshots []rds.DBSnapshot
 	for _, snapshot := range resp {
-		if *snapshot.Status != "available" {
+		if requireAvailable && *snapshot.Status != "available" {
 			log.Debugf("DB Snapshot Status : %s", *snapshot.Status)
 			continue
 		}
 
+		if keep != nil {
+			ok, err := keep(snapshot)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+
 		dbSnapshots = append(dbSnapshots, snapshot)
 	}
 
-	db_len := len(dbSnapshots)
-	if db_len < 1 {
-		log.Errorf("%s", ErrSnapshotNotFound.Error())
-		return nil, ErrSnapshotNotFound
+	return dbSnapshots, nil
+}
+
+func (c *Command) matchesTagFilters(snapshot rds.DBSnapshot, filters map[string]string) (bool, error) {
+	resp, err := c.RDSClient.ListTagsForResource(
+		&rds.ListTagsForResourceMessage{
+			ResourceName: aws.String(c.getARNString(snapshot)),
+		})
+	if err != nil {
+		log.Errorf("%s", err.Error())
+		return false, err
 	}
 
-	return &dbSnapshots[db_len-1], err
+	for key, value := range filters {
+		found := false
+		for _, tag := range resp.TagList {
+			if *tag.Key == key && *tag.Value == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// the target only "available", most recent by SnapshotCreateTime
+func (c *Command) DescribeLatestDBSnapshot(dbIdentifier string) (*rds.DBSnapshot, error) {
+	return c.FindDBSnapshot(SnapshotQuery{
+		DBInstanceIdentifier: dbIdentifier,
+		MostRecent:           true,
+	})
 }
 
 // all status in target, result return only one
@@ -338,6 +542,107 @@ func (c *Command) CreateDBSnapshot(dbIdentifier string) (*rds.DBSnapshot, error)
 	return resp.DBSnapshot, err
 }
 
+const restore_attribute_text = "restore"
+
+// read back the list of AWS account IDs a DB Snapshot is currently shared with
+func (c *Command) DescribeDBSnapshotAttributes(snapshotIdentifier string) (*rds.DBSnapshotAttributesResult, error) {
+	resp, err := c.RDSClient.DescribeDBSnapshotAttributes(
+		&rds.DescribeDBSnapshotAttributesMessage{
+			DBSnapshotIdentifier: aws.String(snapshotIdentifier),
+		})
+
+	if err != nil {
+		log.Errorf("%s", err.Error())
+		return nil, err
+	}
+
+	return resp.DBSnapshotAttributesResult, err
+}
+
+// restoreAttributeValues pulls the current "restore" attribute's account ID list out
+// of a DescribeDBSnapshotAttributes response
+func restoreAttributeValues(attrs *rds.DBSnapshotAttributesResult) []string {
+	if attrs == nil {
+		return nil
+	}
+
+	for _, attr := range attrs.DBSnapshotAttributes {
+		if attr.AttributeName != nil && *attr.AttributeName == restore_attribute_text {
+			return attr.AttributeValues
+		}
+	}
+
+	return nil
+}
+
+// diffDBSnapshotShare drops any requested add that is already in current and any
+// requested remove that isn't, so ModifyDBSnapshotAttribute only ever sees the
+// actual delta instead of re-asserting values that are already correct
+func diffDBSnapshotShare(current, addAccounts, removeAccounts []string) (toAdd, toRemove []string) {
+	currentSet := make(map[string]bool, len(current))
+	for _, account := range current {
+		currentSet[account] = true
+	}
+
+	for _, account := range addAccounts {
+		if !currentSet[account] {
+			toAdd = append(toAdd, account)
+		}
+	}
+
+	for _, account := range removeAccounts {
+		if currentSet[account] {
+			toRemove = append(toRemove, account)
+		}
+	}
+
+	return toAdd, toRemove
+}
+
+// grant or revoke restore access to a DB Snapshot for the given AWS account IDs
+// only snapshots managed by rds-try (see checkListTagsForResourceMessage) may be shared,
+// so this is the one point where the "share" subcommand should hook in
+func (c *Command) ShareDBSnapshot(snapshotIdentifier string, addAccounts, removeAccounts []string) error {
+	snapshot, err := c.DescribeDBSnapshot(snapshotIdentifier)
+	if err != nil {
+		return err
+	}
+
+	state, err := c.checkListTagsForResourceMessage(*snapshot)
+	if err != nil {
+		return err
+	}
+	if !state {
+		log.Errorf("%s", ErrSnapshotNotManaged.Error())
+		return ErrSnapshotNotManaged
+	}
+
+	attrs, err := c.DescribeDBSnapshotAttributes(snapshotIdentifier)
+	if err != nil {
+		return err
+	}
+
+	toAdd, toRemove := diffDBSnapshotShare(restoreAttributeValues(attrs), addAccounts, removeAccounts)
+	if len(toAdd) < 1 && len(toRemove) < 1 {
+		log.Debugf("share state for %s is already up to date", snapshotIdentifier)
+		return nil
+	}
+
+	message := &rds.ModifyDBSnapshotAttributeMessage{
+		DBSnapshotIdentifier: aws.String(snapshotIdentifier),
+		AttributeName:        aws.String(restore_attribute_text),
+		ValuesToAdd:          toAdd,
+		ValuesToRemove:       toRemove,
+	}
+
+	if _, err := c.RDSClient.ModifyDBSnapshotAttribute(message); err != nil {
+		log.Errorf("%s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
 func (c *Command) DeleteDBSnapshot(snapshotIdentifier string) (*rds.DBSnapshot, error) {
 	message := &rds.DeleteDBSnapshotMessage{
 		DBSnapshotIdentifier: aws.String(snapshotIdentifier),
@@ -353,6 +658,192 @@ func (c *Command) DeleteDBSnapshot(snapshotIdentifier string) (*rds.DBSnapshot,
 	return resp.DBSnapshot, err
 }
 
+func (c *Command) describeDBClusters(message *rds.DescribeDBClustersMessage) ([]rds.DBCluster, error) {
+	resp, err := c.RDSClient.DescribeDBClusters(message)
+
+	if err != nil {
+		log.Errorf("%s", err.Error())
+		return nil, err
+	}
+
+	return resp.DBClusters, err
+}
+
+// all status in target, result return only one
+func (c *Command) DescribeDBCluster(clusterIdentifier string) (*rds.DBCluster, error) {
+	message := &rds.DescribeDBClustersMessage{
+		DBClusterIdentifier: aws.String(clusterIdentifier),
+	}
+	resp, err := c.describeDBClusters(message)
+
+	if err != nil {
+		return nil, err
+	}
+
+	db_len := len(resp)
+	if db_len < 1 {
+		log.Errorf("%s", ErrClusterNotFound.Error())
+		return nil, ErrClusterNotFound
+	}
+
+	return &resp[db_len-1], err
+}
+
+func (c *Command) describeDBClusterSnapshots(message *rds.DescribeDBClusterSnapshotsMessage) ([]rds.DBClusterSnapshot, error) {
+	resp, err := c.RDSClient.DescribeDBClusterSnapshots(message)
+
+	if err != nil {
+		log.Errorf("%s", err.Error())
+		return nil, err
+	}
+
+	return resp.DBClusterSnapshots, err
+}
+
+// the target only "available", most recent by SnapshotCreateTime
+func (c *Command) DescribeLatestDBClusterSnapshot(clusterIdentifier string) (*rds.DBClusterSnapshot, error) {
+	message := &rds.DescribeDBClusterSnapshotsMessage{
+		DBClusterIdentifier: aws.String(clusterIdentifier),
+	}
+
+	resp, err := c.describeDBClusterSnapshots(message)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// want to filter by status "available"
+	var dbClusterSnapshots []rds.DBClusterSnapshot
+	for _, snapshot := range resp {
+		if *snapshot.Status != "available" {
+			log.Debugf("DB Cluster Snapshot Status : %s", *snapshot.Status)
+			continue
+		}
+
+		dbClusterSnapshots = append(dbClusterSnapshots, snapshot)
+	}
+
+	db_len := len(dbClusterSnapshots)
+	if db_len < 1 {
+		log.Errorf("%s", ErrClusterSnapshotNotFound.Error())
+		return nil, ErrClusterSnapshotNotFound
+	}
+
+	sort.Slice(dbClusterSnapshots, func(i, j int) bool {
+		return dbClusterSnapshots[i].SnapshotCreateTime.After(*dbClusterSnapshots[j].SnapshotCreateTime)
+	})
+
+	return &dbClusterSnapshots[0], err
+}
+
+// all status in target, result return only one
+func (c *Command) DescribeDBClusterSnapshot(snapshotIdentifier string) (*rds.DBClusterSnapshot, error) {
+	message := &rds.DescribeDBClusterSnapshotsMessage{
+		DBClusterSnapshotIdentifier: aws.String(snapshotIdentifier),
+	}
+
+	resp, err := c.describeDBClusterSnapshots(message)
+
+	if err != nil {
+		return nil, err
+	}
+
+	db_len := len(resp)
+	if db_len < 1 {
+		log.Errorf("%s", ErrClusterSnapshotNotFound.Error())
+		return nil, ErrClusterSnapshotNotFound
+	}
+
+	return &resp[db_len-1], err
+}
+
+func (c *Command) CreateDBClusterSnapshot(clusterIdentifier string) (*rds.DBClusterSnapshot, error) {
+	message := &rds.CreateDBClusterSnapshotMessage{
+		DBClusterIdentifier:         aws.String(clusterIdentifier),
+		DBClusterSnapshotIdentifier: aws.String(utils.GetFormatedDBDisplayName(clusterIdentifier)),
+		Tags:                        getSpecifyTags(), // It must always be set to not forget
+	}
+
+	resp, err := c.RDSClient.CreateDBClusterSnapshot(message)
+
+	if err != nil {
+		log.Errorf("%s", err.Error())
+		return nil, err
+	}
+
+	return resp.DBClusterSnapshot, err
+}
+
+type RestoreDBClusterFromSnapshotArgs struct {
+	DBInstanceClass    string
+	ClusterIdentifier  string
+	InstanceIdentifier string
+	Snapshot           *rds.DBClusterSnapshot
+}
+
+// restore an Aurora DB Cluster from a snapshot, then create its first cluster
+// instance — a cluster has no endpoint to connect to until at least one exists
+func (c *Command) RestoreDBClusterFromSnapshot(args *RestoreDBClusterFromSnapshotArgs) (*rds.DBCluster, error) {
+	message := &rds.RestoreDBClusterFromSnapshotMessage{
+		DBClusterIdentifier: aws.String(args.ClusterIdentifier),
+		SnapshotIdentifier:  args.Snapshot.DBClusterSnapshotIdentifier,
+		Engine:              args.Snapshot.Engine,
+		Tags:                getSpecifyTags(), // It must always be set to not forget
+	}
+
+	resp, err := c.RDSClient.RestoreDBClusterFromSnapshot(message)
+
+	if err != nil {
+		log.Errorf("%s", err.Error())
+		return nil, err
+	}
+
+	instanceMessage := &rds.CreateDBInstanceMessage{
+		DBInstanceIdentifier: aws.String(args.InstanceIdentifier),
+		DBInstanceClass:      aws.String(args.DBInstanceClass),
+		DBClusterIdentifier:  resp.DBCluster.DBClusterIdentifier,
+		Engine:               resp.DBCluster.Engine,
+	}
+
+	if _, err := c.RDSClient.CreateDBInstance(instanceMessage); err != nil {
+		log.Errorf("%s", err.Error())
+		return nil, err
+	}
+
+	return resp.DBCluster, err
+}
+
+func (c *Command) DeleteDBCluster(clusterIdentifier string) (*rds.DBCluster, error) {
+	message := &rds.DeleteDBClusterMessage{
+		DBClusterIdentifier: aws.String(clusterIdentifier),
+		SkipFinalSnapshot:   aws.Boolean(true), // "SkipFinalSnapshot" is always true
+	}
+
+	resp, err := c.RDSClient.DeleteDBCluster(message)
+
+	if err != nil {
+		log.Errorf("%s", err.Error())
+		return nil, err
+	}
+
+	return resp.DBCluster, err
+}
+
+func (c *Command) DeleteDBClusterSnapshot(snapshotIdentifier string) (*rds.DBClusterSnapshot, error) {
+	message := &rds.DeleteDBClusterSnapshotMessage{
+		DBClusterSnapshotIdentifier: aws.String(snapshotIdentifier),
+	}
+
+	resp, err := c.RDSClient.DeleteDBClusterSnapshot(message)
+
+	if err != nil {
+		log.Errorf("%s", err.Error())
+		return nil, err
+	}
+
+	return resp.DBClusterSnapshot, err
+}
+
 // "Pending Status" If the return value is ture
 func (c *Command) CheckPendingStatus(dbInstance *rds.DBInstance) bool {
 	for _, item := range dbInstance.DBParameterGroups {
@@ -389,6 +880,22 @@ func (c *Command) DeleteDBResources(rdstypes interface{}) error {
 			}
 			log.Infof("[% d] deleted DB Instance: %s", i+1, *resp.DBInstanceIdentifier)
 		}
+	case []rds.DBClusterSnapshot:
+		for i, item := range rdstype {
+			resp, err := c.DeleteDBClusterSnapshot(*item.DBClusterSnapshotIdentifier)
+			if err != nil {
+				return err
+			}
+			log.Infof("[% d] deleted DB Cluster Snapshot: %s", i+1, *resp.DBClusterSnapshotIdentifier)
+		}
+	case []rds.DBCluster:
+		for i, item := range rdstype {
+			resp, err := c.DeleteDBCluster(*item.DBClusterIdentifier)
+			if err != nil {
+				return err
+			}
+			log.Infof("[% d] deleted DB Cluster: %s", i+1, *resp.DBClusterIdentifier)
+		}
 	default:
 		log.Errorf("%s", ErrRdsTypesNotFound.Error())
 	}
@@ -396,60 +903,151 @@ func (c *Command) DeleteDBResources(rdstypes interface{}) error {
 	return nil
 }
 
-// wait for status available
-func (c *Command) WaitForStatusAvailable(rdstypes interface{}) <-chan bool {
-	receiver := make(chan bool)
-	// 30 seconds intervals checked
-	ticker := time.NewTicker(30 * time.Second)
-	// 30 minutes time out
-	timeout := time.After(30 * time.Minute)
+var (
+	ErrWaiterTimeout = errors.New("timed out waiting for status")
+	ErrWaiterFailed  = errors.New("entered a terminal failure state")
+)
+
+// Waiter configures how WaitForStatusAvailable polls an RDS resource. The zero value
+// is not ready to use; call NewWaiter or defaultWaiter to get the package defaults.
+type Waiter struct {
+	Interval         time.Duration
+	Timeout          time.Duration
+	MaxAttempts      int
+	AcceptableStates []string // default: "available"
+	FailureStates    []string // default: "failed", "incompatible-restore", "incompatible-network"
+}
+
+// NewWaiter returns the package default Waiter: 30 second interval, 30 minute timeout.
+func NewWaiter() Waiter {
+	return Waiter{
+		Interval:         30 * time.Second,
+		Timeout:          30 * time.Minute,
+		AcceptableStates: []string{"available"},
+		FailureStates:    []string{"failed", "incompatible-restore", "incompatible-network"},
+	}
+}
+
+// defaultWaiter builds a Waiter from the operator-tunable fields on RDSConfig,
+// falling back to NewWaiter's defaults for anything left unset
+func (c *Command) defaultWaiter() Waiter {
+	waiter := NewWaiter()
+
+	if c.RDSConfig.WaiterInterval > 0 {
+		waiter.Interval = c.RDSConfig.WaiterInterval
+	}
+	if c.RDSConfig.WaiterTimeout > 0 {
+		waiter.Timeout = c.RDSConfig.WaiterTimeout
+	}
+	if c.RDSConfig.WaiterMaxAttempts > 0 {
+		waiter.MaxAttempts = c.RDSConfig.WaiterMaxAttempts
+	}
+
+	return waiter
+}
+
+// WaitStatusResult distinguishes "still pending after timeout" (Err == ErrWaiterTimeout)
+// from "entered a terminal failure state" (Err == ErrWaiterFailed) from a describe error.
+type WaitStatusResult struct {
+	Available bool
+	Err       error
+}
+
+// wait for status available (or any of waiter.AcceptableStates)
+func (c *Command) WaitForStatusAvailable(rdstypes interface{}, waiter Waiter) <-chan WaitStatusResult {
+	if waiter.Interval <= 0 {
+		waiter.Interval = 30 * time.Second
+	}
+	if waiter.Timeout <= 0 {
+		waiter.Timeout = 30 * time.Minute
+	}
+	if len(waiter.AcceptableStates) < 1 {
+		waiter.AcceptableStates = []string{"available"}
+	}
+
+	receiver := make(chan WaitStatusResult)
+	ticker := time.NewTicker(waiter.Interval)
+	timeout := time.After(waiter.Timeout)
 
 	go func() {
+		defer ticker.Stop()
+
+		attempts := 0
 		for {
 			select {
 			case tick := <-ticker.C:
-				var rds_status string
-
+				attempts++
 				log.Debugf("tick: %s", tick)
 
+				var rds_status string
+
 				switch rdstype := rdstypes.(type) {
 				case *rds.DBSnapshot:
 					db_snapshot, err := c.DescribeDBSnapshot(*rdstype.DBSnapshotIdentifier)
-
 					if err != nil {
-						receiver <- false
-
-						ticker.Stop()
+						receiver <- WaitStatusResult{Err: err}
+						return
 					}
 
 					rds_status = *db_snapshot.Status
 					log.Infof("DB Snapshot Status: %s", rds_status)
 				case *rds.DBInstance:
 					db_instance, err := c.DescribeDBInstance(*rdstype.DBInstanceIdentifier)
-
 					if err != nil {
-						receiver <- false
-
-						ticker.Stop()
+						receiver <- WaitStatusResult{Err: err}
+						return
 					}
 
 					rds_status = *db_instance.DBInstanceStatus
 					log.Infof("DB Instance Status: %s", rds_status)
+				case *rds.DBCluster:
+					db_cluster, err := c.DescribeDBCluster(*rdstype.DBClusterIdentifier)
+					if err != nil {
+						receiver <- WaitStatusResult{Err: err}
+						return
+					}
+
+					rds_status = *db_cluster.Status
+					log.Infof("DB Cluster Status: %s", rds_status)
+				case *rds.DBClusterSnapshot:
+					db_cluster_snapshot, err := c.DescribeDBClusterSnapshot(*rdstype.DBClusterSnapshotIdentifier)
+					if err != nil {
+						receiver <- WaitStatusResult{Err: err}
+						return
+					}
+
+					rds_status = *db_cluster_snapshot.Status
+					log.Infof("DB Cluster Snapshot Status: %s", rds_status)
 				default:
 					log.Errorf("%s", ErrRdsTypesNotFound.Error())
+					receiver <- WaitStatusResult{Err: ErrRdsTypesNotFound}
+					return
 				}
 
-				if rds_status == "available" {
-					receiver <- true
-					log.Infof("Status: %s", rds_status)
+				for _, state := range waiter.FailureStates {
+					if rds_status == state {
+						log.Errorf("entered failure state: %s", rds_status)
+						receiver <- WaitStatusResult{Err: ErrWaiterFailed}
+						return
+					}
+				}
 
-					ticker.Stop()
+				for _, state := range waiter.AcceptableStates {
+					if rds_status == state {
+						log.Infof("Status: %s", rds_status)
+						receiver <- WaitStatusResult{Available: true}
+						return
+					}
+				}
+
+				if waiter.MaxAttempts > 0 && attempts >= waiter.MaxAttempts {
+					receiver <- WaitStatusResult{Err: ErrWaiterTimeout}
+					return
 				}
 			case out := <-timeout:
-				receiver <- false
 				log.Infof("time out: %s", out)
-
-				ticker.Stop()
+				receiver <- WaitStatusResult{Err: ErrWaiterTimeout}
+				return
 			}
 		}
 	}()
@@ -482,75 +1080,116 @@ func (c *Command) ExecuteSQL(args *ExecuteSQLArgs) ([]time.Duration, error) {
 	for _, value := range args.Queries {
 		log.Debugf("query value : %s", value)
 
-		s_time := time.Now()
-		log.Infof("query start time: %s", s_time)
-
-		result, err := db.Query(value.Sql)
+		duration, err := c.executeSQLQuery(db, value)
 		if err != nil {
-			log.Errorf("%s", err.Error())
 			return times, err
 		}
 
-		e_time := time.Now()
-		log.Infof("query end time: %s", e_time)
+		times = append(times, duration)
+	}
 
-		times = append(times, e_time.Sub(s_time))
+	return times, nil
+}
 
-		// output csv file
-		cols, _ := result.Columns()
-		if c.OutConfig.File && len(cols) > 0 {
-			file_name := value.Name + "-" + utils.GetFormatedTime() + ".csv"
-			out_path := utils.GetHomeDir()
-			if c.OutConfig.Root != "" {
-				out_path = c.OutConfig.Root
-			}
+// executeSQLQuery runs a single query and writes its result through the configured
+// OutputSink, closing the *sql.Rows at the end of this call instead of stacking a
+// defer per query onto ExecuteSQL's own return
+func (c *Command) executeSQLQuery(db *sql.DB, value query.Query) (time.Duration, error) {
+	s_time := time.Now()
+	log.Infof("query start time: %s", s_time)
+
+	result, err := db.Query(value.Sql)
+	if err != nil {
+		log.Errorf("%s", err.Error())
+		return 0, err
+	}
+	defer result.Close()
+
+	e_time := time.Now()
+	log.Infof("query end time: %s", e_time)
+
+	cols, _ := result.ColumnTypes()
+	if c.OutConfig.File && len(cols) > 0 {
+		if err := c.writeResultToSink(value.Name, result, cols); err != nil {
+			log.Errorf("%s", err.Error())
+			return 0, err
+		}
+	}
+
+	return e_time.Sub(s_time), nil
+}
 
-			out_state := writeCSVFile(
-				&writeCSVFileArgs{
-					Rows:     result,
-					FileName: file_name,
-					Path:     out_path,
-					Bom:      c.OutConfig.Bom,
-				})
-			log.Debugf("out_state:%+v", out_state)
+// dbOpenValueBuilder returns the golang db driver name and an engine-specific DSN
+type dbOpenValueBuilder func(cfg config.RDSConfig, endpoint *rds.Endpoint) (string, string)
+
+// convert from "aws engine name" to "golang db driver name" and DSN
+// see also
+// CreateDBInstance - Amazon Relational Database Service
+// http://docs.aws.amazon.com/AmazonRDS/latest/APIReference/API_CreateDBInstance.html
+// Request Parameters "Engine" is Valid Values
+// Valid Values: MySQL | oracle-se1 | oracle-se | oracle-ee | sqlserver-ee | sqlserver-se | sqlserver-ex | sqlserver-web | postgres
+//
+// SQLDrivers · golang/go Wiki · GitHub
+// https://github.com/golang/go/wiki/SQLDrivers
+var dbOpenValueBuilders = map[string]dbOpenValueBuilder{
+	"mysql": func(cfg config.RDSConfig, endpoint *rds.Endpoint) (string, string) {
+		return "mysql", fmt.Sprintf("%s:%s@tcp(%s:%d)/%s", cfg.User, cfg.Pass, *endpoint.Address, *endpoint.Port, cfg.DBName)
+	},
+	"postgres": func(cfg config.RDSConfig, endpoint *rds.Endpoint) (string, string) {
+		sslmode := cfg.SSLMode
+		if sslmode == "" {
+			sslmode = "require"
 		}
+		return "postgres", fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s", cfg.User, cfg.Pass, *endpoint.Address, *endpoint.Port, cfg.DBName, sslmode)
+	},
+	"sqlserver": func(cfg config.RDSConfig, endpoint *rds.Endpoint) (string, string) {
+		return "sqlserver", fmt.Sprintf("sqlserver://%s:%s@%s:%d?database=%s", cfg.User, cfg.Pass, *endpoint.Address, *endpoint.Port, cfg.DBName)
+	},
+	"oracle": func(cfg config.RDSConfig, endpoint *rds.Endpoint) (string, string) {
+		return "oracle", fmt.Sprintf("oracle://%s:%s@%s:%d/%s", cfg.User, cfg.Pass, *endpoint.Address, *endpoint.Port, cfg.DBName)
+	},
+}
+
+// engineFamilyPrefixes maps the "Engine" valid values CreateDBInstance advertises
+// down to the key dbOpenValueBuilders is keyed by. Ordered as prefixes rather than
+// a Contains-based switch since AWS only ever prefixes an engine family name, never
+// embeds it mid-string (e.g. "sqlserver-ex", "aurora-postgresql").
+var engineFamilyPrefixes = []struct {
+	prefix string
+	family string
+}{
+	{"aurora-mysql", "mysql"},
+	{"mysql", "mysql"},
+	{"aurora-postgresql", "postgres"},
+	{"postgres", "postgres"},
+	{"oracle", "oracle"},
+	{"sqlserver", "sqlserver"},
+}
 
-		result.Close()
+// dbEngineFamily maps an aws rds engine name (e.g. "sqlserver-ex") down to the key
+// dbOpenValueBuilders is keyed by
+func dbEngineFamily(engine string) string {
+	for _, p := range engineFamilyPrefixes {
+		if strings.HasPrefix(engine, p.prefix) {
+			return p.family
+		}
 	}
 
-	return times, nil
+	return ""
 }
 
 func (c *Command) getDbOpenValues(args *ExecuteSQLArgs) (string, string) {
-	var driver_name string
-	var data_source_name string
-
 	engine := strings.ToLower(args.Engine)
 	log.Debugf("aws engine name: %s", engine)
 
-	// convert from "aws engine name" to "golang db driver name"
-	// see also
-	// CreateDBInstance - Amazon Relational Database Service
-	// http://docs.aws.amazon.com/AmazonRDS/latest/APIReference/API_CreateDBInstance.html
-	// Request Parameters "Engine" is Valid Values
-	// Valid Values: MySQL | oracle-se1 | oracle-se | oracle-ee | sqlserver-ee | sqlserver-se | sqlserver-ex | sqlserver-web | postgres
-	//
-	// SQLDrivers · golang/go Wiki · GitHub
-	// https://github.com/golang/go/wiki/SQLDrivers
-	//
-	// to-do: correspondence of mysql only
-	switch {
-	case strings.Contains(engine, "mysql"):
-		driver_name = "mysql"
-		data_source_name = fmt.Sprintf("%s:%s@tcp(%s:%d)/", c.RDSConfig.User, c.RDSConfig.Pass, *args.Endpoint.Address, *args.Endpoint.Port)
-	case strings.Contains(engine, "oracle"):
-		driver_name = "oracle"
-	case strings.Contains(engine, "sqlserver"):
-		driver_name = "sqlserver"
-	case strings.Contains(engine, "postgres"):
-		driver_name = "postgres"
-	default:
+	var driver_name string
+	var data_source_name string
+
+	builder, ok := dbOpenValueBuilders[dbEngineFamily(engine)]
+	if !ok {
 		log.Errorf("failed to convert. no matching SQL driver: %s", engine)
+	} else {
+		driver_name, data_source_name = builder(c.RDSConfig, args.Endpoint)
 	}
 
 	log.Debugf("golang db driver name: %s", driver_name)
@@ -571,6 +1210,10 @@ func (c *Command) getARNString(rdstypes interface{}) string {
 		arn = c.ARNPrefix + "snapshot:" + *rdstype.DBSnapshotIdentifier
 	case rds.DBInstance:
 		arn = c.ARNPrefix + "db:" + *rdstype.DBInstanceIdentifier
+	case rds.DBCluster:
+		arn = c.ARNPrefix + "cluster:" + *rdstype.DBClusterIdentifier
+	case rds.DBClusterSnapshot:
+		arn = c.ARNPrefix + "cluster-snapshot:" + *rdstype.DBClusterSnapshotIdentifier
 	default:
 		log.Errorf("%s", ErrRdsARNsNotFound.Error())
 	}
@@ -605,76 +1248,63 @@ func getSpecifyTags() []rds.Tag {
 	return tag_list
 }
 
-type writeCSVFileArgs struct {
-	Rows     *sql.Rows
-	FileName string
-	Path     string
-	Bom      bool
-}
-
-func writeCSVFile(args *writeCSVFileArgs) bool {
-	const BOM = string('\uFEFF')
-
-	cols, err := args.Rows.Columns()
-	if err != nil {
-		log.Errorf("%s", err.Error())
-		return false
+// writeResultToSink drains rows into an OutputSink chosen from c.OutConfig.Format,
+// preserving each column's native type via ColumnTypes instead of flattening to
+// strings the way the old CSV-only writer did.
+func (c *Command) writeResultToSink(queryName string, rows *sql.Rows, cols []*sql.ColumnType) (err error) {
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		names[i] = col.Name()
 	}
 
-	// is append bom?
-	if args.Bom {
-		// When making the extension a txt, UTF8 can be used in Excel.
-		args.FileName = fmt.Sprintf("utf8-bom_%s", args.FileName)
+	sink, err := c.newOutputSink(queryName)
+	if err != nil {
+		return err
 	}
-	out_path := path.Join(args.Path, args.FileName)
-
-	// all user access OK
-	file, err := os.OpenFile(out_path, os.O_WRONLY|os.O_CREATE, 0777)
-	defer file.Close()
 
-	// set empty
-	err = file.Truncate(0)
-
-	// write csv
-	writer := csv.NewWriter(file)
-
-	// add BOM
-	if args.Bom {
-		boms := make([]string, 1)
-		boms[0] = BOM + fmt.Sprintf("# character encoding : utf-8 with BOM")
-		writer.Write(boms)
+	if err := sink.Open(names, cols); err != nil {
+		log.Errorf("%s", err.Error())
+		return err
 	}
+	// named return so a failed Close (the S3 sink's CompleteMultipartUpload/PutObject,
+	// or the Parquet sink's footer flush in WriteStop) is reported to the caller
+	// instead of only logged, which would otherwise report success for a write that
+	// never completed
+	defer func() {
+		if closeErr := sink.Close(); closeErr != nil {
+			log.Errorf("%s", closeErr.Error())
+			if err == nil {
+				err = closeErr
+			}
+		}
+	}()
 
-	// write header
-	writer.Write(cols)
-
-	// Result is your slice string.
-	rawResult := make([][]byte, len(cols))
-	result := make([]string, len(cols))
-
-	// A temporary interface{} slice
 	dest := make([]interface{}, len(cols))
-	// Put pointers to each string in the interface slice
-	for i, _ := range rawResult {
-		dest[i] = &rawResult[i]
+	for i := range dest {
+		dest[i] = new(interface{})
 	}
 
-	for args.Rows.Next() {
-		err = args.Rows.Scan(dest...)
-		if err != nil {
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
 			log.Errorf("%s", err.Error())
+			return err
 		}
 
-		for i, raw := range rawResult {
-			if raw == nil {
-				result[i] = "null"
-			} else {
-				result[i] = string(raw)
-			}
+		values := make([]driver.Value, len(dest))
+		for i, d := range dest {
+			values[i] = *(d.(*interface{}))
+		}
+
+		if err := sink.WriteRow(values); err != nil {
+			log.Errorf("%s", err.Error())
+			return err
 		}
-		writer.Write(result)
 	}
-	writer.Flush()
 
-	return true
+	if err := rows.Err(); err != nil {
+		log.Errorf("%s", err.Error())
+		return err
+	}
+
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,438 @@
+package command
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/xitongsys/parquet-go-source/writerfile"
+	"github.com/xitongsys/parquet-go/writer"
+
+	"github.com/awslabs/aws-sdk-go/aws"
+	"github.com/awslabs/aws-sdk-go/gen/s3"
+
+	"github.com/uchimanajet7/rds-try/config"
+	"github.com/uchimanajet7/rds-try/utils"
+)
+
+// OutputSink receives a query result one row at a time so ExecuteSQL does not need
+// to know the output format or destination (local file vs S3).
+type OutputSink interface {
+	Open(cols []string, types []*sql.ColumnType) error
+	WriteRow(values []driver.Value) error
+	Close() error
+}
+
+// newOutputSink picks a sink implementation from c.OutConfig.Format, falling back to
+// CSV when unset, and a destination writer from c.OutConfig.S3Bucket, falling back to
+// a local file under cfg.Root (or the home dir) the way the old CSV-only writer did.
+func (c *Command) newOutputSink(queryName string) (OutputSink, error) {
+	cfg := c.OutConfig
+
+	format := strings.ToLower(cfg.Format)
+	if format == "" {
+		format = "csv"
+	}
+
+	ext, ok := sinkExtensions[format]
+	if !ok {
+		log.Errorf("unknown output format, falling back to csv: %s", cfg.Format)
+		format = "csv"
+		ext = sinkExtensions["csv"]
+	}
+
+	file_name := fmt.Sprintf("%s-%s.%s", queryName, utils.GetFormatedTime(), ext)
+	if format == "csv" && cfg.Bom {
+		// When making the extension a txt, UTF8 can be used in Excel. A UTF-8 BOM note
+		// is meaningless for ndjson/parquet, so the prefix is CSV-only.
+		file_name = fmt.Sprintf("utf8-bom_%s", file_name)
+	}
+
+	w, err := newSinkWriter(cfg, file_name, c.getS3Client)
+	if err != nil {
+		log.Errorf("%s", err.Error())
+		return nil, err
+	}
+
+	switch format {
+	case "ndjson":
+		return &ndjsonSink{writer: w}, nil
+	case "parquet":
+		return newParquetSink(w)
+	default:
+		return &csvSink{writer: w, bom: cfg.Bom}, nil
+	}
+}
+
+var sinkExtensions = map[string]string{
+	"csv":     "csv",
+	"ndjson":  "ndjson",
+	"parquet": "parquet",
+}
+
+// newSinkWriter opens the destination for a sink: an S3 object when cfg.S3Bucket is
+// set (so large query results never have to hit local disk), otherwise a local file.
+// getS3Client is only called in the S3Bucket case, so a plain local-file run never
+// pays for building an S3 client.
+func newSinkWriter(cfg config.OutConfig, file_name string, getS3Client func() *s3.S3) (io.WriteCloser, error) {
+	if cfg.S3Bucket != "" {
+		key := file_name
+		if cfg.Root != "" {
+			key = path.Join(cfg.Root, file_name)
+		}
+		return newS3Writer(getS3Client(), cfg.S3Bucket, key), nil
+	}
+
+	out_path := utils.GetHomeDir()
+	if cfg.Root != "" {
+		out_path = cfg.Root
+	}
+
+	file, err := os.OpenFile(path.Join(out_path, file_name), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0777)
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// csvSink keeps the old CSV-only writer's behavior: every value is rendered as a
+// string, and NULL is written as the literal text "null".
+type csvSink struct {
+	writer    io.WriteCloser
+	csvWriter *csv.Writer
+	bom       bool
+}
+
+func (s *csvSink) Open(cols []string, types []*sql.ColumnType) error {
+	s.csvWriter = csv.NewWriter(s.writer)
+
+	if s.bom {
+		const BOM = string('\uFEFF')
+		if err := s.csvWriter.Write([]string{BOM + "# character encoding : utf-8 with BOM"}); err != nil {
+			return err
+		}
+	}
+
+	return s.csvWriter.Write(cols)
+}
+
+func (s *csvSink) WriteRow(values []driver.Value) error {
+	row := make([]string, len(values))
+	for i, v := range values {
+		if v == nil {
+			row[i] = "null"
+			continue
+		}
+		if raw, ok := v.([]byte); ok {
+			row[i] = string(raw)
+			continue
+		}
+		row[i] = fmt.Sprintf("%v", v)
+	}
+
+	return s.csvWriter.Write(row)
+}
+
+func (s *csvSink) Close() error {
+	s.csvWriter.Flush()
+	flushErr := s.csvWriter.Error()
+
+	if err := s.writer.Close(); err != nil {
+		return err
+	}
+
+	return flushErr
+}
+
+// ndjsonSink writes one JSON object per row, keeping numeric/bool/null types intact
+// instead of flattening everything to strings.
+type ndjsonSink struct {
+	writer  io.WriteCloser
+	encoder *json.Encoder
+	cols    []string
+	types   []*sql.ColumnType
+}
+
+func (s *ndjsonSink) Open(cols []string, types []*sql.ColumnType) error {
+	s.cols = cols
+	s.types = types
+	s.encoder = json.NewEncoder(s.writer)
+
+	return nil
+}
+
+func (s *ndjsonSink) WriteRow(values []driver.Value) error {
+	row := make(map[string]interface{}, len(s.cols))
+	for i, col := range s.cols {
+		v, err := coerceValue(values[i], s.types[i])
+		if err != nil {
+			return err
+		}
+		row[col] = v
+	}
+
+	return s.encoder.Encode(row)
+}
+
+func (s *ndjsonSink) Close() error {
+	return s.writer.Close()
+}
+
+// parquetSink writes rows through xitongsys/parquet-go's JSON-schema writer, with a
+// schema generated from the query's own column types.
+type parquetSink struct {
+	out    io.WriteCloser // the destination the WriterFile wraps; closed by Close
+	file   *writerfile.WriterFile
+	writer *writer.JSONWriter
+	cols   []string
+	types  []*sql.ColumnType
+}
+
+func newParquetSink(w io.WriteCloser) (OutputSink, error) {
+	return &parquetSink{out: w, file: writerfile.NewWriterFile(w)}, nil
+}
+
+func (s *parquetSink) Open(cols []string, types []*sql.ColumnType) error {
+	s.cols = cols
+	s.types = types
+
+	pw, err := writer.NewJSONWriter(parquetJSONSchema(cols, types), s.file, 1)
+	if err != nil {
+		log.Errorf("%s", err.Error())
+		return err
+	}
+	s.writer = pw
+
+	return nil
+}
+
+func (s *parquetSink) WriteRow(values []driver.Value) error {
+	row := make(map[string]interface{}, len(s.cols))
+	for i, col := range s.cols {
+		v, err := coerceValue(values[i], s.types[i])
+		if err != nil {
+			return err
+		}
+		row[col] = v
+	}
+
+	b, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+
+	return s.writer.Write(string(b))
+}
+
+func (s *parquetSink) Close() error {
+	stopErr := s.writer.WriteStop()
+
+	if err := s.out.Close(); err != nil {
+		return err
+	}
+
+	return stopErr
+}
+
+// parquetJSONSchema builds the JSON schema xitongsys/parquet-go needs, defaulting
+// any SQL type it doesn't recognize to a UTF8 string.
+func parquetJSONSchema(cols []string, types []*sql.ColumnType) string {
+	fields := make([]string, len(cols))
+	for i, col := range cols {
+		fields[i] = fmt.Sprintf(`{"Tag": "name=%s, type=%s, repetitiontype=OPTIONAL"}`, col, parquetType(types[i]))
+	}
+
+	return fmt.Sprintf(`{"Tag": "name=row, repetitiontype=REQUIRED", "Fields": [%s]}`, strings.Join(fields, ","))
+}
+
+// coerceValue converts a scanned driver.Value to the Go type parquetType classifies
+// t as, so NDJSON/Parquet output actually carries the numeric/bool types
+// ColumnTypes() reported instead of everything coming back as a string. The MySQL
+// driver in particular scans every column as []byte regardless of its SQL type, and
+// WriteRow for Parquet must agree with the schema parquetJSONSchema already declared
+// from the very same classification.
+func coerceValue(v driver.Value, t *sql.ColumnType) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	raw, ok := v.([]byte)
+	if !ok {
+		return v, nil
+	}
+
+	switch parquetType(t) {
+	case "INT64":
+		return strconv.ParseInt(string(raw), 10, 64)
+	case "DOUBLE":
+		return strconv.ParseFloat(string(raw), 64)
+	case "BOOLEAN":
+		return strconv.ParseBool(string(raw))
+	default:
+		return string(raw), nil
+	}
+}
+
+func parquetType(t *sql.ColumnType) string {
+	switch strings.ToUpper(t.DatabaseTypeName()) {
+	case "INT", "INTEGER", "BIGINT", "SMALLINT", "TINYINT":
+		return "INT64"
+	case "FLOAT", "DOUBLE", "DECIMAL", "NUMERIC", "REAL":
+		return "DOUBLE"
+	case "BOOL", "BOOLEAN":
+		return "BOOLEAN"
+	default:
+		return "UTF8"
+	}
+}
+
+// getS3Client builds an S3 client from the same credentials and region the command's
+// RDSClient already holds, the way getDstRDSClient builds a second RDS client.
+func (c *Command) getS3Client() *s3.S3 {
+	return s3.New(c.Creds, c.Region, nil)
+}
+
+// s3MinPartSize is S3's minimum size for every part but the last in a multipart
+// upload.
+const s3MinPartSize = 5 * 1024 * 1024
+
+// s3Writer streams a sink's output to S3 via a multipart upload, flushing a part as
+// soon as s3MinPartSize bytes have buffered, so large query results never have to sit
+// fully in memory.
+type s3Writer struct {
+	client   *s3.S3
+	bucket   string
+	key      string
+	uploadID *string
+	partNum  int
+	parts    []s3.CompletedPart
+	buf      bytes.Buffer
+}
+
+func newS3Writer(client *s3.S3, bucket, key string) io.WriteCloser {
+	return &s3Writer{client: client, bucket: bucket, key: key}
+}
+
+func (w *s3Writer) Write(p []byte) (int, error) {
+	n, err := w.buf.Write(p)
+	if err != nil {
+		return n, err
+	}
+
+	for w.buf.Len() >= s3MinPartSize {
+		if err := w.uploadPart(w.buf.Next(s3MinPartSize)); err != nil {
+			w.abort()
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+// abort releases an in-progress multipart upload so a mid-stream failure doesn't
+// leave an incomplete upload billing storage in the bucket forever. A no-op if no
+// multipart upload was ever started.
+func (w *s3Writer) abort() {
+	if w.uploadID == nil {
+		return
+	}
+
+	_, err := w.client.AbortMultipartUpload(
+		&s3.AbortMultipartUploadRequest{
+			Bucket:   aws.String(w.bucket),
+			Key:      aws.String(w.key),
+			UploadId: w.uploadID,
+		})
+	if err != nil {
+		log.Errorf("%s", err.Error())
+	}
+}
+
+func (w *s3Writer) uploadPart(part []byte) error {
+	if w.uploadID == nil {
+		resp, err := w.client.CreateMultipartUpload(
+			&s3.CreateMultipartUploadRequest{
+				Bucket: aws.String(w.bucket),
+				Key:    aws.String(w.key),
+			})
+		if err != nil {
+			log.Errorf("%s", err.Error())
+			return err
+		}
+		w.uploadID = resp.UploadId
+	}
+
+	w.partNum++
+	resp, err := w.client.UploadPart(
+		&s3.UploadPartRequest{
+			Bucket:     aws.String(w.bucket),
+			Key:        aws.String(w.key),
+			UploadId:   w.uploadID,
+			PartNumber: aws.Integer(w.partNum),
+			Body:       bytes.NewReader(part),
+		})
+	if err != nil {
+		log.Errorf("%s", err.Error())
+		return err
+	}
+
+	w.parts = append(w.parts, s3.CompletedPart{
+		ETag:       resp.ETag,
+		PartNumber: aws.Integer(w.partNum),
+	})
+
+	return nil
+}
+
+// Close flushes whatever is still buffered and finishes the upload. A result small
+// enough to never reach s3MinPartSize is sent with a single PutObject instead of a
+// multipart upload with one, undersized part.
+func (w *s3Writer) Close() error {
+	if w.uploadID == nil {
+		_, err := w.client.PutObject(
+			&s3.PutObjectRequest{
+				Bucket: aws.String(w.bucket),
+				Key:    aws.String(w.key),
+				Body:   bytes.NewReader(w.buf.Bytes()),
+			})
+		if err != nil {
+			log.Errorf("%s", err.Error())
+			return err
+		}
+
+		return nil
+	}
+
+	if w.buf.Len() > 0 {
+		if err := w.uploadPart(w.buf.Bytes()); err != nil {
+			w.abort()
+			return err
+		}
+	}
+
+	_, err := w.client.CompleteMultipartUpload(
+		&s3.CompleteMultipartUploadRequest{
+			Bucket:   aws.String(w.bucket),
+			Key:      aws.String(w.key),
+			UploadId: w.uploadID,
+			MultipartUpload: &s3.CompletedMultipartUpload{
+				Parts: w.parts,
+			},
+		})
+	if err != nil {
+		log.Errorf("%s", err.Error())
+		w.abort()
+		return err
+	}
+
+	return nil
+}
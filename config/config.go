@@ -0,0 +1,45 @@
+// Package config holds the settings command.Command needs that come from outside
+// the RDS API itself: how to connect to a restored DB instance, how long to wait on
+// long-running operations, and where/how to write query results.
+package config
+
+import "time"
+
+// RDSConfig carries the DB connection settings ExecuteSQL needs to open a connection
+// to a restored DB Instance, plus the operator-tunable knobs for WaitForStatusAvailable.
+type RDSConfig struct {
+	User string
+	Pass string
+
+	// DBName and SSLMode feed getDbOpenValues' per-engine DSN builders; SSLMode is
+	// only consulted for the postgres DSN and defaults to "require" when empty.
+	DBName  string
+	SSLMode string
+
+	// WaiterInterval, WaiterTimeout, and WaiterMaxAttempts override Waiter's package
+	// defaults (see NewWaiter) when set; a zero value leaves the default in place.
+	WaiterInterval    time.Duration
+	WaiterTimeout     time.Duration
+	WaiterMaxAttempts int
+}
+
+// OutConfig controls how ExecuteSQL writes a query's result set.
+type OutConfig struct {
+	// File, when true, writes results through an OutputSink instead of discarding them.
+	File bool
+
+	// Root is the destination directory for a local-file sink, or the key prefix for
+	// an S3 sink. When empty, a local file goes to the home directory and an S3 key
+	// has no prefix.
+	Root string
+
+	// Bom prepends a UTF-8 byte order mark note to CSV output so Excel opens it as UTF-8.
+	Bom bool
+
+	// Format selects the OutputSink implementation: "csv" (default), "ndjson", or "parquet".
+	Format string
+
+	// S3Bucket routes output to s3://S3Bucket/Root/<query-name>-<ts>.<ext> instead of
+	// a local file.
+	S3Bucket string
+}